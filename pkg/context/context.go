@@ -0,0 +1,57 @@
+// Package context holds the global key/value store used to share variables
+// between steps of a scenario.
+package context
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	instance *Context
+	once     sync.Once
+)
+
+// Context is the global variable store. It is safe for concurrent use: a
+// parallel step runs its sub-steps at once, and each of them may call Add
+// while reading is happening through Patch.
+type Context struct {
+	mu        sync.RWMutex
+	variables map[string]string
+}
+
+// GetContext returns the process-wide Context singleton.
+func GetContext() *Context {
+	once.Do(func() {
+		instance = &Context{variables: make(map[string]string)}
+	})
+	return instance
+}
+
+// Add sets the value of a variable, creating or overwriting it.
+func (c *Context) Add(name string, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.variables[name] = value
+}
+
+// Get returns the value of a variable and whether it was found.
+func (c *Context) Get(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, found := c.variables[name]
+	return value, found
+}
+
+// Patch replaces every "{{name}}" placeholder in input with the matching
+// variable's value. A placeholder with no matching variable is left untouched.
+func (c *Context) Patch(input string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	output := input
+	for name, value := range c.variables {
+		output = strings.ReplaceAll(output, "{{"+name+"}}", value)
+	}
+	return output
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/thomaspoignant/api-scenario/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// grpcRequest invokes a unary or server-streaming gRPC method described by
+// step.Grpc and marshals the response(s) back to JSON, so the existing
+// jsonq-based variable extraction and JSON-path assertions keep working
+// unchanged. A server-streaming method is reported as a JSON array, one
+// element per message received.
+func (sc *stepControllerImpl) grpcRequest(ctx context.Context, step model.Step) (model.ResultStep, error) {
+	start := time.Now()
+	result := model.ResultStep{StepType: model.GrpcStepType}
+
+	conn, err := dialGrpc(ctx, step.Grpc)
+	if err != nil {
+		result.StepTime = time.Now().Sub(start)
+		result.Err = err
+		return result, err
+	}
+	defer conn.Close()
+
+	method, err := resolveMethod(ctx, conn, step.Grpc)
+	if err != nil {
+		result.StepTime = time.Now().Sub(start)
+		result.Err = err
+		return result, err
+	}
+
+	bodyPatched := patch(step.Grpc.Body, "body", &result.VariableApplied)
+	request := dynamic.NewMessage(method.GetInputType())
+	if err := request.UnmarshalJSON([]byte(bodyPatched)); err != nil {
+		result.StepTime = time.Now().Sub(start)
+		result.Err = fmt.Errorf("impossible to build the grpc request: %w", err)
+		return result, result.Err
+	}
+
+	if len(step.Grpc.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(step.Grpc.Metadata))
+	}
+
+	stub := grpcdynamic.NewStub(conn)
+	var jsonBody []byte
+	if method.IsServerStreaming() {
+		jsonBody, err = invokeServerStream(ctx, stub, method, request)
+	} else {
+		jsonBody, err = invokeUnary(ctx, stub, method, request)
+	}
+	elapsed := time.Now().Sub(start)
+	result.StepTime = elapsed
+	if err != nil {
+		result.Err = err
+		return result, err
+	}
+
+	grpcResponse, err := model.NewResponseFromJSON(jsonBody, elapsed)
+	if err != nil {
+		result.Err = err
+		return result, err
+	}
+	result.Response = grpcResponse
+
+	result.Assertion = sc.assertResponse(grpcResponse, step.Assertions)
+	result.VariableCreated = attachVariablesToContext(grpcResponse, step.Variables)
+	return result, nil
+}
+
+// invokeUnary calls a unary method and marshals its single response to JSON.
+func invokeUnary(ctx context.Context, stub grpcdynamic.Stub, method *desc.MethodDescriptor, request *dynamic.Message) ([]byte, error) {
+	response, err := stub.InvokeRpc(ctx, method, request)
+	if err != nil {
+		return nil, err
+	}
+
+	marshaled, err := dynamic.AsDynamicMessage(response)
+	if err != nil {
+		return nil, fmt.Errorf("impossible to read the grpc response: %w", err)
+	}
+	return marshaled.MarshalJSON()
+}
+
+// invokeServerStream calls a server-streaming method and marshals every
+// received message into a single JSON array.
+func invokeServerStream(ctx context.Context, stub grpcdynamic.Stub, method *desc.MethodDescriptor, request *dynamic.Message) ([]byte, error) {
+	stream, err := stub.InvokeRpcServerStream(ctx, method, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []json.RawMessage
+	for {
+		response, err := stream.RecvMsg()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		marshaled, err := dynamic.AsDynamicMessage(response)
+		if err != nil {
+			return nil, fmt.Errorf("impossible to read a grpc stream message: %w", err)
+		}
+		asJSON, err := marshaled.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("impossible to marshal a grpc stream message: %w", err)
+		}
+		messages = append(messages, asJSON)
+	}
+	return json.Marshal(messages)
+}
+
+// dialGrpc opens a connection to the gRPC target, configuring TLS if requested.
+func dialGrpc(ctx context.Context, step model.GrpcStep) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithBlock()}
+
+	if step.TLS.Enabled {
+		tlsConfig := &tls.Config{InsecureSkipVerify: step.TLS.InsecureSkipVerify}
+		if len(step.TLS.CACertFile) > 0 {
+			pool := x509.NewCertPool()
+			pem, err := os.ReadFile(step.TLS.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("impossible to read the grpc CA cert file: %w", err)
+			}
+			pool.AppendCertsFromPEM(pem)
+			tlsConfig.RootCAs = pool
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	return grpc.DialContext(ctx, step.Target, opts...)
+}
+
+// resolveMethod finds the descriptor for a "package.Service/Method" fully
+// qualified method name, using step.ProtoSet when set, or falling back to
+// server reflection against conn otherwise.
+func resolveMethod(ctx context.Context, conn *grpc.ClientConn, step model.GrpcStep) (*desc.MethodDescriptor, error) {
+	if len(step.ProtoSet) > 0 {
+		return resolveMethodFromProtoSet(step.ProtoSet, step.FullMethod)
+	}
+	return resolveMethodFromReflection(ctx, conn, step.FullMethod)
+}
+
+// resolveMethodFromReflection uses server reflection to find the method descriptor.
+func resolveMethodFromReflection(ctx context.Context, conn *grpc.ClientConn, fullMethod string) (*desc.MethodDescriptor, error) {
+	serviceName, methodName, found := strings.Cut(fullMethod, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid grpc method %q, expected \"package.Service/Method\"", fullMethod)
+	}
+
+	client := grpcreflect.NewClientV1Alpha(ctx, reflectpb.NewServerReflectionClient(conn))
+	defer client.Reset()
+
+	serviceDesc, err := client.ResolveService(serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("impossible to resolve grpc service %q: %w", serviceName, err)
+	}
+
+	methodDesc := serviceDesc.FindMethodByName(methodName)
+	if methodDesc == nil {
+		return nil, fmt.Errorf("method %q not found on service %q", methodName, serviceName)
+	}
+	return methodDesc, nil
+}
+
+// resolveMethodFromProtoSet finds the method descriptor from a compiled
+// FileDescriptorSet (as produced by "protoc -o descriptor.protoset"), for
+// servers that don't expose reflection.
+func resolveMethodFromProtoSet(protoSetPath string, fullMethod string) (*desc.MethodDescriptor, error) {
+	serviceName, methodName, found := strings.Cut(fullMethod, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid grpc method %q, expected \"package.Service/Method\"", fullMethod)
+	}
+
+	raw, err := os.ReadFile(protoSetPath)
+	if err != nil {
+		return nil, fmt.Errorf("impossible to read the grpc proto_set file %q: %w", protoSetPath, err)
+	}
+
+	var fdSet descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &fdSet); err != nil {
+		return nil, fmt.Errorf("impossible to parse the grpc proto_set file %q: %w", protoSetPath, err)
+	}
+
+	filesByName, err := desc.CreateFileDescriptorsFromSet(&fdSet)
+	if err != nil {
+		return nil, fmt.Errorf("impossible to load the descriptors from %q: %w", protoSetPath, err)
+	}
+
+	for _, file := range filesByName {
+		if serviceDesc := file.FindService(serviceName); serviceDesc != nil {
+			methodDesc := serviceDesc.FindMethodByName(methodName)
+			if methodDesc == nil {
+				return nil, fmt.Errorf("method %q not found on service %q", methodName, serviceName)
+			}
+			return methodDesc, nil
+		}
+	}
+	return nil, fmt.Errorf("service %q not found in proto_set %q", serviceName, protoSetPath)
+}
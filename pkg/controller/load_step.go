@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sendgrid/rest"
+	"github.com/thomaspoignant/api-scenario/pkg/model"
+	"golang.org/x/time/rate"
+)
+
+// loadRequest sends step's request step.Load.Requests times, spread over
+// step.Load.Workers concurrent workers and rate-limited to step.Load.RPS,
+// sharing the same request-building/assertion path as a single RequestStep.
+//
+// Variable extraction is disabled for every one of those requests: concurrent
+// workers racing to write the "current" response into the shared Context
+// would be meaningless (and a data race), so only assertions and timings are
+// aggregated. See requestOnceOptions.ExtractVariables. Per-request logging is
+// also disabled, since it would otherwise flood stdout once per request.
+func (sc *stepControllerImpl) loadRequest(ctx context.Context, step model.Step) (model.ResultStep, error) {
+	start := time.Now()
+
+	workers, err := validateLoadConfig(step.Load)
+	if err != nil {
+		return model.ResultStep{}, err
+	}
+
+	req, _, err := convertAndPatchToHttpRequest(step)
+	if err != nil {
+		return model.ResultStep{}, err
+	}
+
+	var limiter *rate.Limiter
+	if step.Load.RPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(step.Load.RPS), 1)
+	}
+
+	jobs := make(chan int, step.Load.Requests)
+	for i := 0; i < step.Load.Requests; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	durations := make([]time.Duration, 0, step.Load.Requests)
+	statusCodes := make(map[int]int)
+	errorCount := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				if limiter != nil {
+					_ = limiter.Wait(ctx)
+				}
+
+				// requestOnce's tracer.Inject mutates req.Headers; give every
+				// worker its own copy so concurrent iterations don't race on
+				// (or corrupt) a shared map.
+				workerReq := cloneRequest(req)
+				result := sc.requestOnce(ctx, workerReq, nil, step, requestOnceOptions{ExtractVariables: false, Verbose: false})
+
+				mu.Lock()
+				durations = append(durations, result.StepTime)
+				if result.Err != nil {
+					errorCount++
+				} else {
+					statusCodes[result.Response.StatusCode]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return model.ResultStep{
+		StepType: model.LoadStepType,
+		StepTime: time.Now().Sub(start),
+		Load:     aggregateLoadResult(durations, errorCount, statusCodes),
+	}, nil
+}
+
+// validateLoadConfig checks step.Load and returns the number of workers to
+// use (defaulting Workers to 1 when unset). Requests must be at least 1:
+// make(chan int, step.Load.Requests) in loadRequest would otherwise panic on a
+// negative value or silently send zero requests on a zero one, neither of
+// which is the clear error a misconfigured scenario deserves.
+func validateLoadConfig(load model.LoadConfig) (int, error) {
+	if load.Requests < 1 {
+		return 0, fmt.Errorf("load.requests must be at least 1, got %d", load.Requests)
+	}
+	if load.RPS < 0 {
+		return 0, fmt.Errorf("load.rps must not be negative, got %d", load.RPS)
+	}
+
+	workers := load.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	return workers, nil
+}
+
+// cloneRequest makes a shallow copy of req with its own Headers and
+// QueryParams maps, so concurrent load workers don't share (and race on)
+// the same map instances.
+func cloneRequest(req rest.Request) rest.Request {
+	clone := req
+
+	clone.Headers = make(map[string]string, len(req.Headers))
+	for key, value := range req.Headers {
+		clone.Headers[key] = value
+	}
+
+	clone.QueryParams = make(map[string]string, len(req.QueryParams))
+	for key, value := range req.QueryParams {
+		clone.QueryParams[key] = value
+	}
+
+	return clone
+}
+
+// aggregateLoadResult turns the raw per-request durations into the
+// min/max/percentile summary reported for a load step.
+func aggregateLoadResult(durations []time.Duration, errorCount int, statusCodes map[int]int) model.LoadResult {
+	result := model.LoadResult{
+		Requests:    len(durations),
+		Errors:      errorCount,
+		StatusCodes: statusCodes,
+	}
+	if len(durations) == 0 {
+		return result
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	result.Min = durations[0]
+	result.Max = durations[len(durations)-1]
+	result.P50 = percentile(durations, 50)
+	result.P90 = percentile(durations, 90)
+	result.P99 = percentile(durations, 99)
+	return result
+}
+
+// percentile returns the p-th percentile of a sorted slice of durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := (p * len(sorted)) / 100
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/thomaspoignant/api-scenario/pkg/model"
+)
+
+// parallelRequest runs every sub-step of step.Parallel concurrently and waits
+// for all of them to complete.
+func (sc *stepControllerImpl) parallelRequest(ctx context.Context, step model.Step) (model.ResultStep, error) {
+	start := time.Now()
+
+	results := make([]model.ResultStep, len(step.Parallel))
+	var wg sync.WaitGroup
+	for i, subStep := range step.Parallel {
+		wg.Add(1)
+		go func(i int, subStep model.Step) {
+			defer wg.Done()
+			result, _ := sc.Run(ctx, subStep)
+			results[i] = result
+		}(i, subStep)
+	}
+	wg.Wait()
+
+	return model.ResultStep{
+		StepType: model.ParallelStepType,
+		StepTime: time.Now().Sub(start),
+		Parallel: results,
+	}, nil
+}
@@ -1,60 +1,156 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/jmoiron/jsonq"
 	"github.com/sendgrid/rest"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
-	"github.com/thomaspoignant/api-scenario/pkg/context"
+	"github.com/antchfx/xmlquery"
+	apiscenariocontext "github.com/thomaspoignant/api-scenario/pkg/context"
+	"github.com/thomaspoignant/api-scenario/pkg/metrics"
 	"github.com/thomaspoignant/api-scenario/pkg/model"
+	"github.com/thomaspoignant/api-scenario/pkg/tracing"
 	"github.com/thomaspoignant/api-scenario/pkg/util"
+	"math/rand"
+	"net/http"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type StepController interface {
-	Run(step model.Step) (model.ResultStep, error)
+	Run(ctx context.Context, step model.Step) (model.ResultStep, error)
 }
 
 type stepControllerImpl struct {
 	client RestClient
 	assertionCtrl AssertionController
+	tracer tracing.Tracer
+	// scenarioName is only used as a metrics label; it stays empty (and metrics
+	// are then skipped) unless the controller is built with NewStepControllerWithMetrics.
+	scenarioName string
 }
 
 func NewStepController(client RestClient, assertionCtrl AssertionController) StepController{
 	return &stepControllerImpl{
 		client: client,
 		assertionCtrl: assertionCtrl,
+		tracer: tracing.NewNoopTracer(),
 	}
 }
 
-// Run is running the step and assert it.
-func (sc *stepControllerImpl) Run(step model.Step) (model.ResultStep, error) {
+// NewStepControllerWithTracer is like NewStepController but reports a span per
+// step (and propagates traceparent headers) to the given tracer.
+func NewStepControllerWithTracer(client RestClient, assertionCtrl AssertionController, tracer tracing.Tracer) StepController {
+	return &stepControllerImpl{
+		client: client,
+		assertionCtrl: assertionCtrl,
+		tracer: tracer,
+	}
+}
+
+// NewStepControllerWithMetrics is like NewStepController but publishes
+// Prometheus metrics for every step, labeled with scenarioName. It is meant
+// for "monitor" mode, where a scenario is re-run repeatedly as a synthetic check.
+func NewStepControllerWithMetrics(client RestClient, assertionCtrl AssertionController, scenarioName string) StepController {
+	return &stepControllerImpl{
+		client: client,
+		assertionCtrl: assertionCtrl,
+		tracer: tracing.NewNoopTracer(),
+		scenarioName: scenarioName,
+	}
+}
+
+// Run is running the step and assert it. It honors ctx cancellation: a
+// canceled or expired ctx aborts the step and the pause below.
+func (sc *stepControllerImpl) Run(ctx context.Context, step model.Step) (model.ResultStep, error) {
+	ctx, span := sc.tracer.StartStep(ctx, step.Name, string(step.Method))
+	defer span.End()
+
+	var result model.ResultStep
+	var err error
 
 	switch step.StepType {
 	case model.Pause:
-		return sc.pause(step.Duration)
+		result, err = sc.pause(ctx, step.Duration)
 
 	case model.RequestStep:
-		return sc.request(step)
+		result, err = sc.request(ctx, step)
+
+	case model.GrpcStepType:
+		result, err = sc.grpcRequest(ctx, step)
+
+	case model.ParallelStepType:
+		result, err = sc.parallelRequest(ctx, step)
+
+	case model.LoadStepType:
+		result, err = sc.loadRequest(ctx, step)
 
 	default:
 		// Cannot happen, all value tested
 		return model.ResultStep{}, fmt.Errorf("%s is an invalid step_type", step.StepType)
 	}
+
+	if len(sc.scenarioName) > 0 {
+		metrics.RecordStep(sc.scenarioName, step.Name, step, result)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		return result, err
+	}
+
+	span.SetURL(result.Request.BaseURL)
+	span.SetStatusCode(result.Response.StatusCode)
+	span.SetAssertionCounts(countAssertions(result.Assertion))
+	span.SetVariables(variableNames(result.VariableApplied), variableNames(result.VariableCreated))
+	return result, nil
 }
 
-// pause is stopping the thread during numberOfSecond seconds.
-func (sc *stepControllerImpl) pause(numberOfSecond int) (model.ResultStep, error) {
+// countAssertions splits a slice of ResultAssertion into passed/failed counts for tracing.
+func countAssertions(assertions []model.ResultAssertion) (passed, failed int) {
+	for _, assertion := range assertions {
+		if assertion.Success {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	return passed, failed
+}
+
+// variableNames extracts the variable keys out of a slice of ResultVariable for tracing.
+func variableNames(vars []model.ResultVariable) []string {
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		names = append(names, v.Key)
+	}
+	return names
+}
+
+// pause is stopping the thread during numberOfSecond seconds, or less if ctx is done first.
+func (sc *stepControllerImpl) pause(ctx context.Context, numberOfSecond int) (model.ResultStep, error) {
 	start := time.Now()
 	logrus.Info("------------------------")
 	logrus.Infof("Waiting for %ds", numberOfSecond)
 	// compute pause time and wait
 	duration := time.Duration(numberOfSecond) * time.Second
-	time.Sleep(duration)
+
+	select {
+	case <-ctx.Done():
+		result := model.ResultStep{
+			StepType: model.Pause,
+			StepTime: time.Now().Sub(start),
+			Err:      &model.TimeoutError{Cause: ctx.Err()},
+		}
+		return result, ctx.Err()
+	case <-time.After(duration):
+	}
 
 	result := model.ResultStep{
 		StepType: model.Pause,
@@ -64,7 +160,10 @@ func (sc *stepControllerImpl) pause(numberOfSecond int) (model.ResultStep, error
 }
 
 // request is calling a Rest HTTP endpoint and assert the response.
-func (sc *stepControllerImpl) request(step model.Step) (model.ResultStep, error) {
+// When step.RetryPolicy is set, it is sent again (re-evaluating the assertions
+// each time) as long as one of its RetryOn conditions matches, until
+// MaxAttempts is reached.
+func (sc *stepControllerImpl) request(ctx context.Context, step model.Step) (model.ResultStep, error) {
 	// convert step to api req
 
 	req, variables, err := convertAndPatchToHttpRequest(step)
@@ -72,49 +171,192 @@ func (sc *stepControllerImpl) request(step model.Step) (model.ResultStep, error)
 		return model.ResultStep{}, errors.New("impossible to convert the request")
 	}
 
-	// init the result
+	maxAttempts := step.RetryPolicy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var result model.ResultStep
+	var history model.RetryHistory
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		result = sc.requestOnce(ctx, req, variables, step, defaultRequestOnceOptions)
+
+		reason, shouldRetry := sc.shouldRetry(step.RetryPolicy, result)
+		if attempt > 1 || shouldRetry {
+			history = append(history, model.RetryAttempt{
+				Attempt:    attempt,
+				StatusCode: result.Response.StatusCode,
+				Duration:   result.StepTime,
+				Reason:     reason,
+			})
+		}
+
+		if !shouldRetry || attempt == maxAttempts {
+			break
+		}
+
+		logrus.Infof("Retrying (%d/%d): %s", attempt, maxAttempts, reason)
+		if waitErr := waitBeforeRetry(ctx, step.RetryPolicy, attempt); waitErr != nil {
+			result.Err = &model.TimeoutError{Cause: waitErr}
+			break
+		}
+	}
+	result.RetryHistory = history
+
+	// A connection/response failure aborts the scenario, whether or not a
+	// retry policy is configured: it only ever stops the loop early (nil
+	// result.Err) or exhausts its attempts, it never clears an error that
+	// was never meant to be retried.
+	if result.Err != nil {
+		return result, result.Err
+	}
+	return result, nil
+}
+
+// requestOnceOptions controls the side effects of requestOnce that are only
+// appropriate for a single, user-visible request, not for one of many
+// requests fired concurrently by a load step.
+type requestOnceOptions struct {
+	// ExtractVariables adds the response's extracted values to the shared Context.
+	ExtractVariables bool
+	// Verbose logs the request/response detail (URL, variables, timing) to stdout.
+	Verbose bool
+}
+
+var defaultRequestOnceOptions = requestOnceOptions{ExtractVariables: true, Verbose: true}
+
+// requestOnce sends req exactly once and runs assertions. Variable extraction
+// and per-request logging can be disabled via opts, which a load step does to
+// avoid flooding the log and racing on the shared Context (see loadRequest).
+// If step.Timeout is set, it is applied as a deadline on top of ctx.
+func (sc *stepControllerImpl) requestOnce(ctx context.Context, req rest.Request, variables []model.ResultVariable, step model.Step, opts requestOnceOptions) model.ResultStep {
 	result := model.ResultStep{
 		StepType: model.RequestStep,
-		Request: req,
+		Request:  req,
 	}
 
 	// apply variable on the request
 	result.VariableApplied = variables
 
-	// Display request
-	printRestRequest(req, result.VariableApplied)
+	if opts.Verbose {
+		printRestRequest(req, result.VariableApplied)
+	}
+
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+
+	// propagate the current span as a traceparent header, no-op if tracing is disabled
+	sc.tracer.Inject(ctx, &req)
 
 	// call the API
 	start := time.Now()
-	res, err := sc.client.Send(req)
+	res, err := sc.client.Send(ctx, req)
 	elapsed := time.Now().Sub(start)
 	result.StepTime = elapsed
 	if err != nil {
-		return result, err
+		if ctx.Err() != nil {
+			result.Err = &model.TimeoutError{Cause: ctx.Err()}
+		} else {
+			result.Err = err
+		}
+		return result
 	}
 
-	logrus.Infof("Time elapsed: %v", elapsed)
+	if opts.Verbose {
+		logrus.Infof("Time elapsed: %v", elapsed)
+	}
 
 	// Create a response
 	response, err := model.NewResponse(*res, elapsed)
 	if err != nil {
-		return result, err
+		result.Err = err
+		return result
 	}
 	result.Response = response
 
-	// Check the assertions
-	result.Assertion = sc.assertResponse(response, step.Assertions)
+	// Check the assertions, logging them only in verbose mode
+	if opts.Verbose {
+		result.Assertion = sc.assertResponse(response, step.Assertions)
+	} else {
+		for _, assertion := range step.Assertions {
+			result.Assertion = append(result.Assertion, sc.assertionCtrl.Assert(assertion, response))
+		}
+	}
+
+	if !opts.ExtractVariables {
+		return result
+	}
 
 	// Add variables to context
 	result.VariableCreated = attachVariablesToContext(response, step.Variables)
 
-	if len(result.VariableCreated) > 0 {
+	if opts.Verbose && len(result.VariableCreated) > 0 {
 		logrus.Info("Variables  created:")
 		for _, currentVar := range result.VariableCreated {
 			currentVar.Print()
 		}
 	}
-	return result, nil
+	return result
+}
+
+// shouldRetry evaluates the RetryPolicy against the last attempt and returns
+// the reason to log along with whether another attempt should be made.
+func (sc *stepControllerImpl) shouldRetry(policy model.RetryPolicy, result model.ResultStep) (string, bool) {
+	if policy.MaxAttempts < 2 {
+		return "", false
+	}
+
+	if policy.HasRetryOn(model.RetryOnConnectionError) && result.Err != nil {
+		return "connection error", true
+	}
+
+	if policy.HasRetryOn(model.RetryOnStatusCode) {
+		for _, code := range policy.StatusCodes {
+			if code == result.Response.StatusCode {
+				return fmt.Sprintf("status code %d", code), true
+			}
+		}
+	}
+
+	if policy.HasRetryOn(model.RetryOnAssertionFailed) {
+		for _, assertion := range result.Assertion {
+			if !assertion.Success {
+				return "assertion failed", true
+			}
+		}
+	}
+
+	if policy.HasRetryOn(model.RetryOnResponseTime) && policy.MaxResponseTime > 0 && result.StepTime > policy.MaxResponseTime {
+		return fmt.Sprintf("response time %v exceeded %v", result.StepTime, policy.MaxResponseTime), true
+	}
+
+	return "", false
+}
+
+// waitBeforeRetry sleeps according to the configured backoff before the next
+// attempt, or returns ctx.Err() early if ctx is canceled or times out first.
+func waitBeforeRetry(ctx context.Context, policy model.RetryPolicy, attempt int) error {
+	delay := policy.InitialDelay
+	switch policy.Backoff {
+	case model.BackoffLinear:
+		delay = policy.InitialDelay * time.Duration(attempt)
+	case model.BackoffExponential:
+		delay = policy.InitialDelay * time.Duration(1<<uint(attempt-1))
+	}
+
+	if policy.Jitter {
+		delay += time.Duration(rand.Int63n(int64(policy.InitialDelay) + 1))
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
 }
 
 // assertResponse assert the response of a REST Call.
@@ -144,13 +386,13 @@ func attachVariablesToContext(response model.Response, vars []model.Variable) []
 		switch variable.Source {
 		case model.ResponseTime:
 			value := strconv.FormatInt(int64(response.TimeElapsed.Round(time.Millisecond)/time.Millisecond), 10)
-			context.GetContext().Add(variable.Name, value)
+			apiscenariocontext.GetContext().Add(variable.Name, value)
 			result = append(result, model.ResultVariable{Key: variable.Name, NewValue: value, Type:  model.Created})
 			break
 
 		case model.ResponseStatus:
 			value := fmt.Sprintf("%v", response.StatusCode)
-			context.GetContext().Add(variable.Name, value)
+			apiscenariocontext.GetContext().Add(variable.Name, value)
 			result = append(result, model.ResultVariable{Key: variable.Name, NewValue: value, Type:  model.Created})
 			break
 
@@ -158,7 +400,7 @@ func attachVariablesToContext(response model.Response, vars []model.Variable) []
 			header := response.Header[variable.Property]
 			if header != nil && len(header)>0 {
 				// TODO: Works fine if we have only one value for the header
-				context.GetContext().Add(variable.Name, header[0])
+				apiscenariocontext.GetContext().Add(variable.Name, header[0])
 				result = append(result, model.ResultVariable{Key: variable.Name, NewValue: header[0], Type:  model.Created})
 			}
 			break
@@ -174,19 +416,19 @@ func attachVariablesToContext(response model.Response, vars []model.Variable) []
 
 			switch value := extractedKey.(type) {
 			case string:
-				context.GetContext().Add(variable.Name, value)
+				apiscenariocontext.GetContext().Add(variable.Name, value)
 				result = append(result, model.ResultVariable{Key: variable.Name, NewValue: value, Type:  model.Created})
 				break
 
 			case bool:
 				castValue := strconv.FormatBool(value)
-				context.GetContext().Add(variable.Name, castValue)
+				apiscenariocontext.GetContext().Add(variable.Name, castValue)
 				result = append(result, model.ResultVariable{Key: variable.Name, NewValue: castValue, Type:  model.Created})
 				break
 
 			case float64:
 				castValue := fmt.Sprintf("%g", value)
-				context.GetContext().Add(variable.Name, castValue)
+				apiscenariocontext.GetContext().Add(variable.Name, castValue)
 				result = append(result, model.ResultVariable{Key: variable.Name, NewValue: castValue, Type:  model.Created})
 				break
 
@@ -198,11 +440,117 @@ func attachVariablesToContext(response model.Response, vars []model.Variable) []
 				})
 				break
 			}
+
+		case model.ResponseXml:
+			value, err := extractXPath(response.RawBody, variable.Property)
+			if err != nil {
+				result = append(result, model.ResultVariable{Key: variable.Name, Err: err, Type: model.Created})
+				break
+			}
+			apiscenariocontext.GetContext().Add(variable.Name, value)
+			result = append(result, model.ResultVariable{Key: variable.Name, NewValue: value, Type: model.Created})
+			break
+
+		case model.ResponseRegex:
+			value, err := extractRegex(response.RawBody, variable.Property)
+			if err != nil {
+				result = append(result, model.ResultVariable{Key: variable.Name, Err: err, Type: model.Created})
+				break
+			}
+			apiscenariocontext.GetContext().Add(variable.Name, value)
+			result = append(result, model.ResultVariable{Key: variable.Name, NewValue: value, Type: model.Created})
+			break
+
+		case model.ResponseCookie:
+			value, err := extractCookie(response.Header, variable.Property)
+			if err != nil {
+				result = append(result, model.ResultVariable{Key: variable.Name, Err: err, Type: model.Created})
+				break
+			}
+			apiscenariocontext.GetContext().Add(variable.Name, value)
+			result = append(result, model.ResultVariable{Key: variable.Name, NewValue: value, Type: model.Created})
+			break
 		}
 	}
 	return result
 }
 
+// extractXPath evaluates an XPath expression against an XML body and returns
+// the matched node's text content.
+func extractXPath(rawBody string, xpath string) (string, error) {
+	if len(xpath) == 0 {
+		return "", errors.New("xpath expression is required for a ResponseXml variable")
+	}
+
+	doc, err := xmlquery.Parse(strings.NewReader(rawBody))
+	if err != nil {
+		return "", fmt.Errorf("impossible to parse the response as XML: %w", err)
+	}
+
+	node := xmlquery.FindOne(doc, xpath)
+	if node == nil {
+		return "", fmt.Errorf("no node found for xpath %q", xpath)
+	}
+	return node.InnerText(), nil
+}
+
+// extractRegex applies a regular expression to the raw response body and
+// returns the value of the named capture group. property has the form
+// "<pattern>|<groupName>". Splitting on the *last* "|" (rather than the
+// first) keeps this unambiguous even when pattern itself uses "|" for
+// alternation, since a capture group name can never contain one.
+func extractRegex(rawBody string, property string) (string, error) {
+	sep := strings.LastIndex(property, "|")
+	if sep == -1 {
+		return "", fmt.Errorf("invalid regex property %q, expected \"<pattern>|<groupName>\"", property)
+	}
+	pattern, groupName := property[:sep], property[sep+1:]
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+	}
+
+	match := re.FindStringSubmatch(rawBody)
+	if match == nil {
+		return "", fmt.Errorf("no match found for pattern %q", pattern)
+	}
+
+	for i, name := range re.SubexpNames() {
+		if name == groupName {
+			return match[i], nil
+		}
+	}
+	return "", fmt.Errorf("no capture group named %q in pattern %q", groupName, pattern)
+}
+
+// extractCookie reads a cookie from the response's Set-Cookie headers.
+// property is either "<cookieName>" for the cookie value, or
+// "<cookieName>.<Attribute>" for one of its attributes (Expires, Domain, Path).
+func extractCookie(header http.Header, property string) (string, error) {
+	cookieName, attribute, _ := strings.Cut(property, ".")
+
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		if cookie.Name != cookieName {
+			continue
+		}
+
+		switch attribute {
+		case "":
+			return cookie.Value, nil
+		case "Expires":
+			return cookie.Expires.String(), nil
+		case "Domain":
+			return cookie.Domain, nil
+		case "Path":
+			return cookie.Path, nil
+		default:
+			return "", fmt.Errorf("unsupported cookie attribute %q", attribute)
+		}
+	}
+	return "", fmt.Errorf("no cookie named %q in the response", cookieName)
+}
+
 // convertAndPatchToHttpRequest create the HTTP request to call.
 func convertAndPatchToHttpRequest(step model.Step) (rest.Request, []model.ResultVariable, error) {
 
@@ -223,7 +571,7 @@ func convertAndPatchToHttpRequest(step model.Step) (rest.Request, []model.Result
 	// Add headers from command line.
 	// It can override existing headers.
 	for key, value := range viper.GetStringMapString("headers") {
-		headers[key] = context.GetContext().Patch(value)
+		headers[key] = apiscenariocontext.GetContext().Patch(value)
 	}
 
 	// Patches
@@ -249,7 +597,7 @@ func convertAndPatchToHttpRequest(step model.Step) (rest.Request, []model.Result
 // update the slice of 'variables"
 func patch(initial string, name string, variables *[]model.ResultVariable) string {
 	initialValue := string(initial)
-	patchedValue := context.GetContext().Patch(initial)
+	patchedValue := apiscenariocontext.GetContext().Patch(initial)
 
 	if initialValue != patchedValue {
 		*variables = append(*variables, model.ResultVariable{
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sendgrid/rest"
+	"github.com/thomaspoignant/api-scenario/pkg/model"
+)
+
+func TestExtractXPath(t *testing.T) {
+	body := `<root><status>ready</status></root>`
+
+	value, err := extractXPath(body, "//status")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "ready" {
+		t.Errorf("expected %q, got %q", "ready", value)
+	}
+
+	if _, err := extractXPath(body, "//missing"); err == nil {
+		t.Error("expected an error when the xpath matches nothing")
+	}
+
+	if _, err := extractXPath(body, ""); err == nil {
+		t.Error("expected an error when the xpath is empty")
+	}
+}
+
+func TestExtractRegex(t *testing.T) {
+	body := "request-id: abc-123"
+
+	value, err := extractRegex(body, `request-id: (?P<id>[\w-]+)|id`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "abc-123" {
+		t.Errorf("expected %q, got %q", "abc-123", value)
+	}
+
+	if _, err := extractRegex(body, `request-id: (?P<id>[\w-]+)`); err == nil {
+		t.Error("expected an error when the property has no group name")
+	}
+
+	// The pattern itself contains a "|" for alternation: splitting on the
+	// first "|" would wrongly cut the pattern in two, so this only works if
+	// extractRegex splits on the last "|" instead.
+	bodyAlt := "request-kind: abc-123"
+	valueAlt, err := extractRegex(bodyAlt, `(request-id|request-kind): (?P<id>[\w-]+)|id`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if valueAlt != "abc-123" {
+		t.Errorf("expected %q, got %q", "abc-123", valueAlt)
+	}
+
+	if _, err := extractRegex(body, `nope: (?P<id>[\w-]+)|id`); err == nil {
+		t.Error("expected an error when the pattern does not match")
+	}
+}
+
+func TestNewResponseExtraction(t *testing.T) {
+	res := rest.Response{
+		StatusCode: 200,
+		Headers: map[string][]string{
+			"Set-Cookie": {"session=abc123; Domain=example.com; Path=/"},
+		},
+		Body: `<root><status>ready</status></root>`,
+	}
+
+	response, err := model.NewResponse(res, 42*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.RawBody != res.Body {
+		t.Fatalf("expected RawBody %q, got %q", res.Body, response.RawBody)
+	}
+
+	value, err := extractXPath(response.RawBody, "//status")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "ready" {
+		t.Errorf("expected %q, got %q", "ready", value)
+	}
+
+	vars := attachVariablesToContext(response, []model.Variable{
+		{Name: "status", Source: model.ResponseXml, Property: "//status"},
+		{Name: "session", Source: model.ResponseCookie, Property: "session"},
+	})
+	for _, created := range vars {
+		if created.Err != nil {
+			t.Errorf("unexpected error extracting %q: %v", created.Key, created.Err)
+		}
+	}
+}
+
+func TestExtractCookie(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=abc123; Domain=example.com; Path=/")
+
+	value, err := extractCookie(header, "session")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value != "abc123" {
+		t.Errorf("expected %q, got %q", "abc123", value)
+	}
+
+	domain, err := extractCookie(header, "session.Domain")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if domain != "example.com" {
+		t.Errorf("expected %q, got %q", "example.com", domain)
+	}
+
+	if _, err := extractCookie(header, "unknown"); err == nil {
+		t.Error("expected an error for a cookie that is not set")
+	}
+}
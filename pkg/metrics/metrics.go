@@ -0,0 +1,88 @@
+// Package metrics exposes a Prometheus /metrics endpoint so that api-scenario
+// can be scraped directly when used in "monitor" mode (repeatedly re-running
+// a scenario as a synthetic check), without needing an external wrapper.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/thomaspoignant/api-scenario/pkg/model"
+)
+
+var (
+	stepDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "api_scenario_step_duration_seconds",
+		Help: "Duration of a scenario step, in seconds.",
+	}, []string{"scenario", "step", "method", "status"})
+
+	assertionFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_scenario_assertion_failures_total",
+		Help: "Number of failed assertions.",
+	}, []string{"scenario", "step", "comparison"})
+
+	variableExtractionErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_scenario_variable_extraction_errors_total",
+		Help: "Number of errors while extracting a variable from a response.",
+	}, []string{"scenario", "step"})
+
+	runSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "api_scenario_run_success",
+		Help: "Whether the last run of the scenario succeeded (1) or not (0).",
+	}, []string{"scenario"})
+)
+
+// RecordStep publishes the counters and histograms for one executed step. It
+// is meant to be called after every StepController.Run, using the labels
+// carried by ResultStep.
+func RecordStep(scenarioName, stepName string, step model.Step, result model.ResultStep) {
+	status := ""
+	if result.Response.StatusCode != 0 {
+		status = strconv.Itoa(result.Response.StatusCode)
+	}
+
+	stepDuration.WithLabelValues(scenarioName, stepName, string(step.Method), status).Observe(result.StepTime.Seconds())
+
+	for _, assertion := range result.Assertion {
+		if !assertion.Success {
+			assertionFailures.WithLabelValues(scenarioName, stepName, string(assertion.Comparison)).Inc()
+		}
+	}
+
+	for _, variable := range result.VariableCreated {
+		if variable.Err != nil {
+			variableExtractionErrors.WithLabelValues(scenarioName, stepName).Inc()
+		}
+	}
+}
+
+// RecordRunResult sets the api_scenario_run_success gauge for a whole scenario run.
+func RecordRunResult(scenarioName string, success bool) {
+	value := 0.0
+	if success {
+		value = 1.0
+	}
+	runSuccess.WithLabelValues(scenarioName).Set(value)
+}
+
+// Serve starts an HTTP server exposing /metrics on addr (e.g. ":9090"), and
+// blocks until ctx is done.
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
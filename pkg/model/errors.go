@@ -0,0 +1,21 @@
+package model
+
+import "fmt"
+
+// TimeoutError is returned by a step when it is aborted because its context
+// was done before completion: its deadline (the step's own timeout, or the
+// parent context's) was reached, or the context was canceled. Cause holds
+// ctx.Err(), so context.DeadlineExceeded tells a real timeout apart from a
+// cancellation. Keeping it as a distinct type lets retry logic and reports
+// tell it apart from a regular HTTP-level failure.
+type TimeoutError struct {
+	Cause error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("step timed out: %v", e.Cause)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,26 @@
+package model
+
+// GrpcStepType marks a Step whose Grpc field describes a unary or
+// server-streaming gRPC call, invoked alongside the existing RequestStep type.
+const GrpcStepType StepType = "grpc"
+
+// GrpcStep describes a gRPC call. The request Body is patched with context
+// variables exactly like RequestStep.Body, and the response is marshalled
+// back to JSON so the existing jsonq-based variable extraction and
+// JSON-path assertions apply unchanged.
+type GrpcStep struct {
+	Target      string            `mapstructure:"target"`
+	FullMethod  string            `mapstructure:"method"`
+	Body        string            `mapstructure:"body"`
+	Metadata    map[string]string `mapstructure:"metadata"`
+	ProtoSet    string            `mapstructure:"proto_set"`
+	TLS         GrpcTLS           `mapstructure:"tls"`
+}
+
+// GrpcTLS configures transport security for a GrpcStep. An empty GrpcTLS
+// means plaintext.
+type GrpcTLS struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CACertFile         string `mapstructure:"ca_cert_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
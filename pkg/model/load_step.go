@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// LoadStepType marks a Step whose Load field describes a single request run
+// N times with a given concurrency and rate limit, for synthetic load testing.
+const LoadStepType StepType = "load"
+
+// LoadConfig is the configuration of a LoadStepType step. The request itself
+// is the step's own Method/Url/Headers/Body, identical to a RequestStep.
+type LoadConfig struct {
+	Requests int `mapstructure:"requests"`
+	Workers  int `mapstructure:"workers"`
+	RPS      int `mapstructure:"rps"`
+}
+
+// LoadResult aggregates the timings of every request sent during a load step.
+//
+// No variables are extracted during a load step: none of its responses is
+// written to the shared Context, so a load step creates no variables.
+type LoadResult struct {
+	Requests       int             `json:"requests"`
+	Errors         int             `json:"errors"`
+	Min            time.Duration   `json:"min"`
+	Max            time.Duration   `json:"max"`
+	P50            time.Duration   `json:"p50"`
+	P90            time.Duration   `json:"p90"`
+	P99            time.Duration   `json:"p99"`
+	StatusCodes    map[int]int     `json:"status_codes"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// ParallelStepType marks a Step whose Parallel field lists sub-steps to run
+// concurrently instead of sequentially.
+const ParallelStepType StepType = "parallel"
+
+// ResultParallel is the set of results of the sub-steps of a ParallelStepType step,
+// in the same order as the sub-steps, even though they ran concurrently.
+type ResultParallel []ResultStep
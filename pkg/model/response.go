@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sendgrid/rest"
+)
+
+// Response is the normalized result of a step's call. It is shared by every
+// step type (HTTP request, gRPC, ...) so that JSON-path assertions and
+// variable extraction work the same regardless of the transport.
+type Response struct {
+	StatusCode  int
+	Header      http.Header
+	Body        map[string]interface{}
+	RawBody     string
+	TimeElapsed time.Duration
+}
+
+// NewResponse builds a Response out of a rest.Response. The body is decoded
+// as JSON into Body when possible, but RawBody always keeps the raw text
+// regardless of its content type, so that the ResponseXml and ResponseRegex
+// variable sources can operate on XML or plain-text bodies too.
+func NewResponse(res rest.Response, elapsed time.Duration) (Response, error) {
+	header := http.Header{}
+	for key, values := range res.Headers {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	var body map[string]interface{}
+	// A non-JSON body (XML, plain text, ...) is not an error here: RawBody
+	// still carries it for the XPath/regex variable sources.
+	_ = json.Unmarshal([]byte(res.Body), &body)
+
+	return Response{
+		StatusCode:  res.StatusCode,
+		Header:      header,
+		Body:        body,
+		RawBody:     res.Body,
+		TimeElapsed: elapsed,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NewResponseFromJSON builds a Response out of a raw JSON payload rather than
+// a rest.Response, so that non-HTTP steps (e.g. a GrpcStep) can reuse the
+// existing jsonq-based variable extraction and JSON-path assertions.
+func NewResponseFromJSON(body []byte, elapsed time.Duration) (Response, error) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return Response{}, fmt.Errorf("impossible to decode the response body as JSON: %w", err)
+	}
+
+	return Response{
+		StatusCode:  0,
+		Header:      http.Header{},
+		Body:        decoded,
+		RawBody:     string(body),
+		TimeElapsed: elapsed,
+	}, nil
+}
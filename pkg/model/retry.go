@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// RetryBackoff describes how the delay between two attempts grows.
+type RetryBackoff string
+
+const (
+	// BackoffConstant keeps the delay identical between every attempt.
+	BackoffConstant RetryBackoff = "constant"
+	// BackoffLinear increases the delay by the initial delay on every attempt.
+	BackoffLinear RetryBackoff = "linear"
+	// BackoffExponential doubles the delay on every attempt.
+	BackoffExponential RetryBackoff = "exponential"
+)
+
+// RetryOn describes a condition that triggers a new attempt.
+type RetryOn string
+
+const (
+	// RetryOnStatusCode retries when the response status code is in RetryPolicy.StatusCodes.
+	RetryOnStatusCode RetryOn = "status_code"
+	// RetryOnConnectionError retries when RestClient.Send returns a transport error.
+	RetryOnConnectionError RetryOn = "connection_error"
+	// RetryOnAssertionFailed retries when at least one assertion fails.
+	RetryOnAssertionFailed RetryOn = "assertion_failed"
+	// RetryOnResponseTime retries when the response took longer than RetryPolicy.MaxResponseTime.
+	RetryOnResponseTime RetryOn = "response_time"
+)
+
+// RetryPolicy is the per-step retry configuration.
+//
+// It is opt-in: a zero value RetryPolicy (MaxAttempts == 0) means the step is
+// sent exactly once, preserving the previous behavior.
+type RetryPolicy struct {
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	InitialDelay    time.Duration `mapstructure:"initial_delay"`
+	Backoff         RetryBackoff  `mapstructure:"backoff"`
+	Jitter          bool          `mapstructure:"jitter"`
+	RetryOn         []RetryOn     `mapstructure:"retry_on"`
+	StatusCodes     []int         `mapstructure:"status_codes"`
+	MaxResponseTime time.Duration `mapstructure:"max_response_time"`
+}
+
+// HasRetryOn returns true if the policy is configured to retry on the given condition.
+func (r RetryPolicy) HasRetryOn(condition RetryOn) bool {
+	for _, c := range r.RetryOn {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryAttempt is a compact record of a single attempt, kept so reports can show flakiness.
+type RetryAttempt struct {
+	Attempt    int           `json:"attempt"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Reason     string        `json:"reason,omitempty"`
+}
+
+// RetryHistory is the ordered list of every attempt of a retried step, the final
+// one included. It is empty when the first attempt triggered no retry.
+type RetryHistory []RetryAttempt
@@ -0,0 +1,16 @@
+package model
+
+// ResponseXml extracts a Variable's value from the response body using an
+// XPath expression (variable.Property), for XML/SOAP responses.
+//
+// ResponseRegex extracts a Variable's value from the raw response body text
+// using a named capture group (variable.Property is "pattern|groupName").
+//
+// ResponseCookie extracts a Variable's value from a Set-Cookie header
+// (variable.Property is "cookieName" or "cookieName.Attribute", e.g.
+// "session.Expires" or "session.Domain").
+const (
+	ResponseXml    Source = "ResponseXml"
+	ResponseRegex  Source = "ResponseRegex"
+	ResponseCookie Source = "ResponseCookie"
+)
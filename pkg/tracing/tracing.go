@@ -0,0 +1,129 @@
+// Package tracing provides optional OpenTelemetry instrumentation for
+// scenario and step execution. It is disabled by default: NewNoopTracer
+// returns a Tracer whose spans are no-ops, so behavior is unchanged unless
+// a real Tracer is configured.
+package tracing
+
+import (
+	"context"
+
+	"github.com/sendgrid/rest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span is the span handle used outside this package; the OpenTelemetry
+// implementation wraps a trace.Span.
+type Span interface {
+	SetURL(url string)
+	SetStatusCode(code int)
+	SetAssertionCounts(passed, failed int)
+	SetVariables(applied, created []string)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts spans for scenario and step execution, and can inject
+// W3C traceparent headers into an outgoing rest.Request.
+type Tracer interface {
+	StartScenario(ctx context.Context, name string) (context.Context, Span)
+	StartStep(ctx context.Context, name string, method string) (context.Context, Span)
+	Inject(ctx context.Context, req *rest.Request)
+}
+
+// NewNoopTracer returns a Tracer that does nothing, used when tracing is disabled.
+func NewNoopTracer() Tracer {
+	return noopTracer{}
+}
+
+// NewTracer returns a Tracer backed by the given OpenTelemetry trace.Tracer,
+// typically obtained from an OTLP TracerProvider configured by the caller.
+func NewTracer(tracer trace.Tracer) Tracer {
+	return &otelTracer{tracer: tracer}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) StartScenario(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTracer) StartStep(ctx context.Context, name, method string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTracer) Inject(ctx context.Context, req *rest.Request) {}
+
+type noopSpan struct{}
+
+func (noopSpan) SetURL(string)                    {}
+func (noopSpan) SetStatusCode(int)                {}
+func (noopSpan) SetAssertionCounts(int, int)      {}
+func (noopSpan) SetVariables([]string, []string)  {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t *otelTracer) StartScenario(ctx context.Context, name string) (context.Context, Span) {
+	ctx, span := t.tracer.Start(ctx, "scenario."+name)
+	return ctx, &otelSpan{span: span}
+}
+
+func (t *otelTracer) StartStep(ctx context.Context, name, method string) (context.Context, Span) {
+	ctx, span := t.tracer.Start(ctx, "step."+name, trace.WithAttributes(
+		attribute.String("http.method", method),
+	))
+	return ctx, &otelSpan{span: span}
+}
+
+// Inject propagates ctx's span as a W3C traceparent header on req, via a
+// headers map since rest.Request is not an *http.Request.
+func (t *otelTracer) Inject(ctx context.Context, req *rest.Request) {
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(req.Headers))
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetURL(url string) {
+	s.span.SetAttributes(attribute.String("http.url", url))
+}
+
+func (s *otelSpan) SetStatusCode(code int) {
+	s.span.SetAttributes(attribute.Int("http.status_code", code))
+}
+
+func (s *otelSpan) SetAssertionCounts(passed, failed int) {
+	s.span.SetAttributes(
+		attribute.Int("assertion.passed", passed),
+		attribute.Int("assertion.failed", failed),
+	)
+	if failed > 0 {
+		s.span.SetStatus(codes.Error, "assertion failed")
+	}
+}
+
+func (s *otelSpan) SetVariables(applied, created []string) {
+	s.span.SetAttributes(
+		attribute.StringSlice("variable.applied", applied),
+		attribute.StringSlice("variable.created", created),
+	)
+}
+
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}